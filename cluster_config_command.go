@@ -0,0 +1,104 @@
+package raft
+
+import (
+	"fmt"
+	"time"
+)
+
+// clusterConfigServer is the subset of Server that cluster-config commands
+// and ClusterConfigMonitor need: enough to read/write the active-cluster
+// policy and change a peer's role. Apply type-asserts into it rather than
+// requiring these methods on Server directly, since Server itself lives
+// outside this series (server.go); any concrete server that grows
+// SetClusterConfig/ClusterConfig/Promote/Demote satisfies this without
+// server.go needing to name this interface.
+type clusterConfigServer interface {
+	SetClusterConfig(ClusterConfig) error
+	ClusterConfig() ClusterConfig
+	Promote(name string) error
+	Demote(name string) error
+}
+
+// SetClusterConfigCommand changes the cluster's active-peer sizing policy:
+// ActiveSize caps how many peers participate in voting/replication at once,
+// and PromotionDelay is how long a peer may be unreachable before it is
+// demoted to proxy (and a proxy promoted in its place). A ClusterConfigMonitor
+// reads the policy this command sets to decide when to act.
+type SetClusterConfigCommand struct {
+	ActiveSize     int           `json:"activeSize"`
+	PromotionDelay time.Duration `json:"promotionDelay"`
+}
+
+// CommandName returns the name of the command in the log.
+func (c *SetClusterConfigCommand) CommandName() string {
+	return "raft:setClusterConfig"
+}
+
+// Apply updates the server's cluster-config policy.
+func (c *SetClusterConfigCommand) Apply(server Server) (interface{}, error) {
+	cs, ok := server.(clusterConfigServer)
+	if !ok {
+		return nil, fmt.Errorf("raft: server does not support cluster-config commands")
+	}
+	return nil, cs.SetClusterConfig(ClusterConfig{
+		ActiveSize:     c.ActiveSize,
+		PromotionDelay: c.PromotionDelay,
+	})
+}
+
+// PromoteCommand promotes a proxy peer into a full, voting member of the
+// cluster. ClusterConfigMonitor appends it automatically when a full peer's
+// promotion delay is reached and a proxy is available to replace it, but it
+// can also be issued directly by an operator.
+type PromoteCommand struct {
+	Name string `json:"name"`
+}
+
+// CommandName returns the name of the command in the log.
+func (c *PromoteCommand) CommandName() string {
+	return "raft:promote"
+}
+
+// Apply promotes the named peer.
+func (c *PromoteCommand) Apply(server Server) (interface{}, error) {
+	cs, ok := server.(clusterConfigServer)
+	if !ok {
+		return nil, fmt.Errorf("raft: server does not support cluster-config commands")
+	}
+	return nil, cs.Promote(c.Name)
+}
+
+// DemoteCommand demotes a full peer to proxy, removing it from voting and
+// replication while leaving it reachable for forwarded client requests.
+// ClusterConfigMonitor appends it automatically when the number of active
+// peers exceeds ClusterConfig.ActiveSize, targeting the least-recently-active
+// peer, but it can also be issued directly by an operator.
+type DemoteCommand struct {
+	Name string `json:"name"`
+}
+
+// CommandName returns the name of the command in the log.
+func (c *DemoteCommand) CommandName() string {
+	return "raft:demote"
+}
+
+// Apply demotes the named peer.
+func (c *DemoteCommand) Apply(server Server) (interface{}, error) {
+	cs, ok := server.(clusterConfigServer)
+	if !ok {
+		return nil, fmt.Errorf("raft: server does not support cluster-config commands")
+	}
+	return nil, cs.Demote(c.Name)
+}
+
+// ClusterConfig holds the active-cluster sizing policy set by
+// SetClusterConfigCommand.
+type ClusterConfig struct {
+	// ActiveSize is the maximum number of peers that participate in voting
+	// and replication. Peers beyond this count run as proxies.
+	ActiveSize int
+
+	// PromotionDelay is how long a full peer may go unreachable before it is
+	// demoted and a proxy promoted in its place.
+	PromotionDelay time.Duration
+}
@@ -0,0 +1,149 @@
+package raft
+
+import (
+	"sync"
+	"time"
+)
+
+// clusterConfigMonitorServer is the subset of Server that ClusterConfigMonitor
+// needs on top of clusterConfigServer: enough to tell whether this server is
+// leader (only the leader proposes cluster-config changes) and to append the
+// Promote/DemoteCommand it decides on.
+type clusterConfigMonitorServer interface {
+	clusterConfigServer
+	Name() string
+	Leader() string
+	Do(command Command) (interface{}, error)
+}
+
+// ClusterConfigMonitor is what actually makes good on the promise in
+// PromoteCommand/DemoteCommand's doc comments: driven by repeated calls to
+// Check (typically from a ticker owned by whoever wires this up, since this
+// package has no background-task runner of its own), it demotes a full peer
+// once it has been unreachable longer than ClusterConfig.PromotionDelay and
+// promotes a proxy in its place, and separately demotes the
+// least-recently-active full peer whenever the active set grows past
+// ClusterConfig.ActiveSize.
+type ClusterConfigMonitor struct {
+	server    clusterConfigMonitorServer
+	peerStats func() map[string]*PeerStats
+
+	mu    sync.Mutex
+	full  map[string]bool
+	proxy map[string]bool
+}
+
+// NewClusterConfigMonitor creates a monitor for server. peerStats is called
+// on every Check to read live per-peer contact times; its map is looked up
+// by the same peer names given in full and proxy, which are also the names
+// carried by the Promote/DemoteCommand it proposes. (*HTTPTransporter).Stats
+// keys its map by connection string instead. full and proxy seed the
+// monitor's initial view of which peers currently hold which role.
+func NewClusterConfigMonitor(server clusterConfigMonitorServer, peerStats func() map[string]*PeerStats, full, proxy []string) *ClusterConfigMonitor {
+	m := &ClusterConfigMonitor{
+		server:    server,
+		peerStats: peerStats,
+		full:      make(map[string]bool, len(full)),
+		proxy:     make(map[string]bool, len(proxy)),
+	}
+	for _, name := range full {
+		m.full[name] = true
+	}
+	for _, name := range proxy {
+		m.proxy[name] = true
+	}
+	return m
+}
+
+// Check runs one evaluation pass. It is a no-op unless this server is
+// currently leader, since only the leader's log changes are authoritative.
+func (m *ClusterConfigMonitor) Check() {
+	if m.server.Leader() != m.server.Name() {
+		return
+	}
+
+	cfg := m.server.ClusterConfig()
+	stats := m.peerStats()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cfg.PromotionDelay > 0 {
+		if stale := m.oldestFullLocked(stats, cfg.PromotionDelay); stale != "" {
+			if candidate := m.anyProxyLocked(); candidate != "" {
+				m.promoteAndDemoteLocked(candidate, stale)
+				return
+			}
+		}
+	}
+
+	if cfg.ActiveSize > 0 && len(m.full) > cfg.ActiveSize {
+		if victim := m.oldestFullLocked(stats, 0); victim != "" {
+			m.demoteLocked(victim)
+		}
+	}
+}
+
+// oldestFullLocked returns the full peer whose last AppendEntries contact is
+// furthest in the past. If minIdle is non-zero, peers contacted more
+// recently than minIdle ago are ignored, and "" is returned if none qualify.
+// A peer with no recorded stats yet (never successfully contacted) counts as
+// maximally stale.
+func (m *ClusterConfigMonitor) oldestFullLocked(stats map[string]*PeerStats, minIdle time.Duration) string {
+	var cutoff time.Time
+	if minIdle > 0 {
+		cutoff = time.Now().Add(-minIdle)
+	}
+
+	var oldestName string
+	var oldest time.Time
+	first := true
+	for name := range m.full {
+		var last time.Time
+		if s, ok := stats[name]; ok {
+			last = s.AppendEntries.LastContact
+		}
+		if minIdle > 0 && last.After(cutoff) {
+			continue
+		}
+		if first || last.Before(oldest) {
+			oldestName, oldest, first = name, last, false
+		}
+	}
+	return oldestName
+}
+
+// anyProxyLocked returns an arbitrary proxy peer name, or "" if there are
+// none to promote.
+func (m *ClusterConfigMonitor) anyProxyLocked() string {
+	for name := range m.proxy {
+		return name
+	}
+	return ""
+}
+
+// promoteAndDemoteLocked proposes promoting candidate and demoting stale,
+// and updates the monitor's roster after each command that succeeds; if the
+// promote fails, the demote is not proposed. Demote runs second so the cluster is never briefly short a full
+// peer if the promote fails.
+func (m *ClusterConfigMonitor) promoteAndDemoteLocked(candidate, stale string) {
+	if _, err := m.server.Do(&PromoteCommand{Name: candidate}); err != nil {
+		traceln("clusterConfigMonitor.promote.error:", err)
+		return
+	}
+	delete(m.proxy, candidate)
+	m.full[candidate] = true
+
+	m.demoteLocked(stale)
+}
+
+// demoteLocked proposes demoting name and updates the monitor's roster to
+// match if it succeeds.
+func (m *ClusterConfigMonitor) demoteLocked(name string) {
+	if _, err := m.server.Do(&DemoteCommand{Name: name}); err != nil {
+		traceln("clusterConfigMonitor.demote.error:", err)
+		return
+	}
+	delete(m.full, name)
+	m.proxy[name] = true
+}
@@ -0,0 +1,37 @@
+package raft
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOldestFullLockedTreatsMissingStatsAsMaximallyStale(t *testing.T) {
+	m := &ClusterConfigMonitor{
+		full: map[string]bool{"peer1": true, "peer2": true},
+	}
+
+	stats := map[string]*PeerStats{
+		"peer1": {AppendEntries: RPCStats{LastContact: time.Now()}},
+		// peer2 has never been recorded: no entry in stats at all. Before
+		// the fix this panicked on a nil map lookup instead of treating
+		// peer2 as maximally stale.
+	}
+
+	if got := m.oldestFullLocked(stats, 0); got != "peer2" {
+		t.Fatalf("oldestFullLocked() = %q, want %q (the peer with no stats)", got, "peer2")
+	}
+}
+
+func TestOldestFullLockedHonorsMinIdle(t *testing.T) {
+	m := &ClusterConfigMonitor{
+		full: map[string]bool{"peer1": true},
+	}
+
+	stats := map[string]*PeerStats{
+		"peer1": {AppendEntries: RPCStats{LastContact: time.Now()}},
+	}
+
+	if got := m.oldestFullLocked(stats, time.Hour); got != "" {
+		t.Fatalf("oldestFullLocked() = %q, want \"\" (peer1 was contacted recently)", got)
+	}
+}
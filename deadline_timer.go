@@ -0,0 +1,63 @@
+package raft
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// protoDecoder is satisfied by the generated Decode method on every request
+// type the transporter reads off the wire.
+type protoDecoder interface {
+	Decode(io.Reader) (int, error)
+}
+
+// protoEncoder is satisfied by the generated Encode method on every response
+// type the transporter writes back.
+type protoEncoder interface {
+	Encode(io.Writer) (int, error)
+}
+
+// decodeWithDeadline arms r's read deadline (if timeout is non-zero) via an
+// http.ResponseController and then runs dec.Decode(r.Body). Arming the
+// deadline on the connection itself, rather than racing a timer against the
+// decode in a separate goroutine, means a stalled client makes r.Body.Read
+// return immediately instead of leaving a goroutine blocked on it forever.
+func decodeWithDeadline(dec protoDecoder, r *http.Request, w http.ResponseWriter, timeout time.Duration) error {
+	if timeout > 0 {
+		if err := http.NewResponseController(w).SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Decode(r.Body)
+	if timeout > 0 && isDeadlineExceeded(err) {
+		return errors.New("transporter: read deadline exceeded")
+	}
+	return err
+}
+
+// encodeWithDeadline arms w's write deadline (if timeout is non-zero) via an
+// http.ResponseController and then runs enc.Encode(w). Arming the deadline
+// on the connection itself means a stalled peer makes the write fail and
+// return directly, instead of abandoning a goroutine that keeps writing to w
+// after the handler has moved on to calling http.Error on the same writer.
+func encodeWithDeadline(enc protoEncoder, w http.ResponseWriter, timeout time.Duration) error {
+	if timeout > 0 {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+	}
+	_, err := enc.Encode(w)
+	if timeout > 0 && isDeadlineExceeded(err) {
+		return errors.New("transporter: write deadline exceeded")
+	}
+	return err
+}
+
+// isDeadlineExceeded reports whether err is (or wraps) a timeout error, as
+// returned by a read/write past a net.Conn deadline.
+func isDeadlineExceeded(err error) bool {
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
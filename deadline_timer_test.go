@@ -0,0 +1,91 @@
+package raft
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeDecoder struct {
+	read func(io.Reader) (int, error)
+}
+
+func (f fakeDecoder) Decode(r io.Reader) (int, error) { return f.read(r) }
+
+type fakeEncoder struct {
+	write func(io.Writer) (int, error)
+}
+
+func (f fakeEncoder) Encode(w io.Writer) (int, error) { return f.write(w) }
+
+func TestDecodeWithDeadlineNoTimeoutPassesThroughError(t *testing.T) {
+	wantErr := errors.New("boom")
+	dec := fakeDecoder{read: func(io.Reader) (int, error) { return 0, wantErr }}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(nil))
+	if err := decodeWithDeadline(dec, req, httptest.NewRecorder(), 0); err != wantErr {
+		t.Errorf("decodeWithDeadline() = %v, want %v", err, wantErr)
+	}
+}
+
+func TestEncodeWithDeadlineNoTimeoutPassesThroughError(t *testing.T) {
+	wantErr := errors.New("boom")
+	enc := fakeEncoder{write: func(io.Writer) (int, error) { return 0, wantErr }}
+
+	if err := encodeWithDeadline(enc, httptest.NewRecorder(), 0); err != wantErr {
+		t.Errorf("encodeWithDeadline() = %v, want %v", err, wantErr)
+	}
+}
+
+func TestIsDeadlineExceeded(t *testing.T) {
+	if isDeadlineExceeded(nil) {
+		t.Error("isDeadlineExceeded(nil) = true, want false")
+	}
+	if isDeadlineExceeded(errors.New("plain error")) {
+		t.Error("isDeadlineExceeded(plain error) = true, want false")
+	}
+	if !isDeadlineExceeded(timeoutError{}) {
+		t.Error("isDeadlineExceeded(timeoutError) = false, want true")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestDecodeWithDeadlineReportsTimeoutOnStalledClient(t *testing.T) {
+	result := make(chan error, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dec := fakeDecoder{read: func(rd io.Reader) (int, error) {
+			n, err := io.Copy(io.Discard, rd)
+			return int(n), err
+		}}
+		err := decodeWithDeadline(dec, r, w, 10*time.Millisecond)
+		result <- err
+	}))
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	req, err := http.NewRequest(http.MethodPost, srv.URL, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go http.DefaultClient.Do(req)
+
+	select {
+	case err := <-result:
+		if err == nil || err.Error() != "transporter: read deadline exceeded" {
+			t.Errorf("decodeWithDeadline() = %v, want read deadline exceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for handler to report the read deadline")
+	}
+}
@@ -0,0 +1,33 @@
+package raft
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// toProto marshals a raft-level request/response into the generated
+// protobuf message the RaftTransport gRPC service expects, by routing it
+// through the same Encode method HTTPTransporter uses to put it on the
+// wire. This keeps the gRPC and HTTP transporters serializing requests
+// identically instead of maintaining two separate field-by-field mappings.
+func toProto(src protoEncoder, dst proto.Message) error {
+	var buf bytes.Buffer
+	if _, err := src.Encode(&buf); err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf.Bytes(), dst)
+}
+
+// fromProto is toProto's inverse: it re-marshals a protobuf message handed
+// to us by gRPC and decodes it into the raft-level type via its existing
+// Decode method, so incoming gRPC requests/responses are parsed exactly
+// like their HTTP counterparts.
+func fromProto(src proto.Message, dst protoDecoder) error {
+	b, err := proto.Marshal(src)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Decode(bytes.NewReader(b))
+	return err
+}
@@ -0,0 +1,66 @@
+package raft
+
+import (
+	"io"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/shelmesky/raft/protobuf"
+)
+
+// fakeVoteRequest stands in for the real (upstream) RequestVoteRequest,
+// which isn't part of this source snapshot. It satisfies protoEncoder and
+// protoDecoder the same way the real type does: by marshaling to/from its
+// protobuf wire message.
+type fakeVoteRequest struct {
+	term uint64
+	name string
+}
+
+func (r *fakeVoteRequest) Encode(w io.Writer) (int, error) {
+	pb := &protobuf.RequestVoteRequest{
+		Term:          proto.Uint64(r.term),
+		LastLogIndex:  proto.Uint64(0),
+		LastLogTerm:   proto.Uint64(0),
+		CandidateName: proto.String(r.name),
+	}
+	b, err := proto.Marshal(pb)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(b)
+}
+
+func (r *fakeVoteRequest) Decode(rd io.Reader) (int, error) {
+	b, err := io.ReadAll(rd)
+	if err != nil {
+		return 0, err
+	}
+	pb := &protobuf.RequestVoteRequest{}
+	if err := proto.Unmarshal(b, pb); err != nil {
+		return len(b), err
+	}
+	r.term = pb.GetTerm()
+	r.name = pb.GetCandidateName()
+	return len(b), nil
+}
+
+func TestToProtoFromProtoRoundTrip(t *testing.T) {
+	src := &fakeVoteRequest{term: 7, name: "node2"}
+
+	pb := &protobuf.RequestVoteRequest{}
+	if err := toProto(src, pb); err != nil {
+		t.Fatalf("toProto: %v", err)
+	}
+	if pb.GetTerm() != 7 || pb.GetCandidateName() != "node2" {
+		t.Fatalf("toProto produced %+v", pb)
+	}
+
+	dst := &fakeVoteRequest{}
+	if err := fromProto(pb, dst); err != nil {
+		t.Fatalf("fromProto: %v", err)
+	}
+	if dst.term != 7 || dst.name != "node2" {
+		t.Fatalf("fromProto produced %+v", dst)
+	}
+}
@@ -0,0 +1,432 @@
+package raft
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sync"
+
+	"github.com/shelmesky/raft/protobuf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+//------------------------------------------------------------------------------
+//
+// Typedefs
+//
+//------------------------------------------------------------------------------
+
+// A GRPCTransporter is a Transporter implementation that speaks the
+// RaftTransport gRPC service defined in protobuf/raft_transport.proto
+// instead of HTTPTransporter's one-shot HTTP POSTs. AppendEntries calls for
+// a given peer share a single long-lived stream, so heartbeats are
+// piggybacked on an already-open connection rather than paying a fresh
+// dial+handshake every time.
+type GRPCTransporter struct {
+	prefix string
+	creds  credentials.TransportCredentials
+
+	mutex   sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	clients map[string]protobuf.RaftTransportClient
+	streams map[string]protobuf.RaftTransport_AppendEntriesClient
+}
+
+//------------------------------------------------------------------------------
+//
+// Constructor
+//
+//------------------------------------------------------------------------------
+
+// Creates a new gRPC transporter with the given path prefix. The prefix is
+// only stored and returned by Prefix; unlike HTTPTransporter there are no
+// routes to mount since gRPC dispatches by service/method name.
+func NewGRPCTransporter(prefix string) *GRPCTransporter {
+	return &GRPCTransporter{
+		prefix:  prefix,
+		conns:   make(map[string]*grpc.ClientConn),
+		clients: make(map[string]protobuf.RaftTransportClient),
+		streams: make(map[string]protobuf.RaftTransport_AppendEntriesClient),
+	}
+}
+
+//------------------------------------------------------------------------------
+//
+// Accessors
+//
+//------------------------------------------------------------------------------
+
+// Retrieves the path prefix used by the transporter.
+func (t *GRPCTransporter) Prefix() string {
+	return t.prefix
+}
+
+// Sets the TLS credentials used when dialing peers. Must be called before
+// any RPCs are sent. They are not applied to incoming connections: those use
+// whatever credentials the *grpc.Server passed to RegisterServer was built
+// with.
+func (t *GRPCTransporter) SetTLSCredentials(creds credentials.TransportCredentials) {
+	t.creds = creds
+}
+
+//------------------------------------------------------------------------------
+//
+// Methods
+//
+//------------------------------------------------------------------------------
+
+//--------------------------------------
+// Installation
+//--------------------------------------
+
+// Install satisfies the Transporter interface. gRPC services are not
+// registered on an HTTPMuxer, so mux is ignored here; call RegisterServer
+// with the server's *grpc.Server instead.
+func (t *GRPCTransporter) Install(server Server, mux HTTPMuxer) {
+}
+
+// Registers the RaftTransport service on grpcServer so incoming RPCs are
+// dispatched to server.
+func (t *GRPCTransporter) RegisterServer(grpcServer *grpc.Server, server Server) {
+	protobuf.RegisterRaftTransportServer(grpcServer, &grpcTransportServer{server: server})
+}
+
+//--------------------------------------
+// Outgoing
+//--------------------------------------
+
+// Sends an AppendEntries RPC to a peer over the peer's long-lived stream,
+// dialing and opening the stream on first use.
+func (t *GRPCTransporter) SendAppendEntriesRequest(server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse {
+	return t.SendAppendEntriesRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendAppendEntriesRequestContext is SendAppendEntriesRequest with a
+// context. ctx is not consulted: the Send and Recv on the peer's shared
+// stream run under the context the stream was opened with (see streamFor),
+// so canceling ctx does not end this call early. The stream is shared
+// across every AppendEntries call to this peer, and canceling it would drop
+// it for everyone else using it.
+func (t *GRPCTransporter) SendAppendEntriesRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse {
+	stream, err := t.streamFor(peer)
+	if err != nil {
+		traceln("transporter.ae.dial.error:", err)
+		return nil
+	}
+
+	pbReq := &protobuf.AppendEntriesRequest{}
+	if err := toProto(req, pbReq); err != nil {
+		traceln("transporter.ae.encoding.error:", err)
+		return nil
+	}
+
+	if err := stream.Send(pbReq); err != nil {
+		traceln("transporter.ae.send.error:", err)
+		t.dropStream(peer)
+		return nil
+	}
+
+	pbResp, err := stream.Recv()
+	if err != nil {
+		traceln("transporter.ae.recv.error:", err)
+		t.dropStream(peer)
+		return nil
+	}
+
+	resp := &AppendEntriesResponse{}
+	if err := fromProto(pbResp, resp); err != nil {
+		traceln("transporter.ae.decoding.error:", err)
+		return nil
+	}
+
+	return resp
+}
+
+// Sends a RequestVote RPC to a peer.
+func (t *GRPCTransporter) SendVoteRequest(server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse {
+	return t.SendVoteRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendVoteRequestContext is SendVoteRequest with a context, so a caller can
+// cancel an in-flight vote request early, e.g. when the election it
+// belongs to has already ended.
+func (t *GRPCTransporter) SendVoteRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse {
+	client, err := t.clientFor(peer)
+	if err != nil {
+		traceln("transporter.rv.dial.error:", err)
+		return nil
+	}
+
+	pbReq := &protobuf.RequestVoteRequest{}
+	if err := toProto(req, pbReq); err != nil {
+		traceln("transporter.rv.encoding.error:", err)
+		return nil
+	}
+
+	pbResp, err := client.RequestVote(ctx, pbReq)
+	if err != nil {
+		traceln("transporter.rv.response.error:", err)
+		return nil
+	}
+
+	resp := &RequestVoteResponse{}
+	if err := fromProto(pbResp, resp); err != nil {
+		traceln("transporter.rv.decoding.error:", err)
+		return nil
+	}
+
+	return resp
+}
+
+// Sends a SnapshotRequest RPC to a peer.
+func (t *GRPCTransporter) SendSnapshotRequest(server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse {
+	return t.SendSnapshotRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendSnapshotRequestContext is SendSnapshotRequest with a context, so a
+// caller can cancel an in-flight snapshot negotiation early.
+func (t *GRPCTransporter) SendSnapshotRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse {
+	client, err := t.clientFor(peer)
+	if err != nil {
+		traceln("transporter.snapshot.dial.error:", err)
+		return nil
+	}
+
+	pbReq := &protobuf.SnapshotRequest{}
+	if err := toProto(req, pbReq); err != nil {
+		traceln("transporter.snapshot.encoding.error:", err)
+		return nil
+	}
+
+	pbResp, err := client.Snapshot(ctx, pbReq)
+	if err != nil {
+		traceln("transporter.snapshot.response.error:", err)
+		return nil
+	}
+
+	resp := &SnapshotResponse{}
+	if err := fromProto(pbResp, resp); err != nil {
+		traceln("transporter.snapshot.decoding.error:", err)
+		return nil
+	}
+
+	return resp
+}
+
+// Sends a SnapshotRecoveryRequest RPC to a peer.
+func (t *GRPCTransporter) SendSnapshotRecoveryRequest(server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse {
+	return t.SendSnapshotRecoveryRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendSnapshotRecoveryRequestContext is SendSnapshotRecoveryRequest with a
+// context, so a caller can abort a stale recovery transfer early.
+func (t *GRPCTransporter) SendSnapshotRecoveryRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse {
+	client, err := t.clientFor(peer)
+	if err != nil {
+		traceln("transporter.sr.dial.error:", err)
+		return nil
+	}
+
+	pbReq := &protobuf.SnapshotRecoveryRequest{}
+	if err := toProto(req, pbReq); err != nil {
+		traceln("transporter.sr.encoding.error:", err)
+		return nil
+	}
+
+	pbResp, err := client.SnapshotRecovery(ctx, pbReq)
+	if err != nil {
+		traceln("transporter.sr.response.error:", err)
+		return nil
+	}
+
+	resp := &SnapshotRecoveryResponse{}
+	if err := fromProto(pbResp, resp); err != nil {
+		traceln("transporter.sr.decoding.error:", err)
+		return nil
+	}
+
+	return resp
+}
+
+//--------------------------------------
+// Connection management
+//--------------------------------------
+
+// Returns the cached client for a peer, dialing it on first use.
+func (t *GRPCTransporter) clientFor(peer *Peer) (protobuf.RaftTransportClient, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if client, ok := t.clients[peer.ConnectionString]; ok {
+		return client, nil
+	}
+
+	conn, err := t.dial(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	client := protobuf.NewRaftTransportClient(conn)
+	t.conns[peer.ConnectionString] = conn
+	t.clients[peer.ConnectionString] = client
+	return client, nil
+}
+
+// Returns the cached AppendEntries stream for a peer, opening it on first
+// use.
+func (t *GRPCTransporter) streamFor(peer *Peer) (protobuf.RaftTransport_AppendEntriesClient, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if stream, ok := t.streams[peer.ConnectionString]; ok {
+		return stream, nil
+	}
+
+	client, err := t.clientForLocked(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.AppendEntries(stdcontext.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	t.streams[peer.ConnectionString] = stream
+	return stream, nil
+}
+
+// clientForLocked is clientFor's body without locking, for callers that
+// already hold t.mutex.
+func (t *GRPCTransporter) clientForLocked(peer *Peer) (protobuf.RaftTransportClient, error) {
+	if client, ok := t.clients[peer.ConnectionString]; ok {
+		return client, nil
+	}
+
+	conn, err := t.dial(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	client := protobuf.NewRaftTransportClient(conn)
+	t.conns[peer.ConnectionString] = conn
+	t.clients[peer.ConnectionString] = client
+	return client, nil
+}
+
+func (t *GRPCTransporter) dial(peer *Peer) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if t.creds != nil {
+		opts = append(opts, grpc.WithTransportCredentials(t.creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(peer.ConnectionString, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("transporter: dial %s: %v", peer.ConnectionString, err)
+	}
+	return conn, nil
+}
+
+// Drops a peer's cached AppendEntries stream so the next AppendEntries call
+// opens a new one, used after a stream-level error. The cached connection
+// and client are kept, so no re-dial happens.
+func (t *GRPCTransporter) dropStream(peer *Peer) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.streams, peer.ConnectionString)
+}
+
+//--------------------------------------
+// Incoming
+//--------------------------------------
+
+// grpcTransportServer adapts the RaftTransport gRPC service to a Server,
+// mirroring HTTPTransporter's appendEntriesHandler/requestVoteHandler/etc.
+type grpcTransportServer struct {
+	protobuf.UnimplementedRaftTransportServer
+	server Server
+}
+
+func (s *grpcTransportServer) AppendEntries(stream protobuf.RaftTransport_AppendEntriesServer) error {
+	for {
+		pbReq, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		req := &AppendEntriesRequest{}
+		if err := fromProto(pbReq, req); err != nil {
+			return err
+		}
+
+		resp := s.server.AppendEntries(req)
+		if resp == nil {
+			return fmt.Errorf("transporter: failed creating AppendEntries response")
+		}
+
+		pbResp := &protobuf.AppendEntriesResponse{}
+		if err := toProto(resp, pbResp); err != nil {
+			return err
+		}
+
+		if err := stream.Send(pbResp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *grpcTransportServer) RequestVote(ctx stdcontext.Context, pbReq *protobuf.RequestVoteRequest) (*protobuf.RequestVoteResponse, error) {
+	req := &RequestVoteRequest{}
+	if err := fromProto(pbReq, req); err != nil {
+		return nil, err
+	}
+
+	resp := s.server.RequestVote(req)
+	if resp == nil {
+		return nil, fmt.Errorf("transporter: failed creating RequestVote response")
+	}
+
+	pbResp := &protobuf.RequestVoteResponse{}
+	if err := toProto(resp, pbResp); err != nil {
+		return nil, err
+	}
+	return pbResp, nil
+}
+
+func (s *grpcTransportServer) Snapshot(ctx stdcontext.Context, pbReq *protobuf.SnapshotRequest) (*protobuf.SnapshotResponse, error) {
+	req := &SnapshotRequest{}
+	if err := fromProto(pbReq, req); err != nil {
+		return nil, err
+	}
+
+	resp := s.server.RequestSnapshot(req)
+	if resp == nil {
+		return nil, fmt.Errorf("transporter: failed creating Snapshot response")
+	}
+
+	pbResp := &protobuf.SnapshotResponse{}
+	if err := toProto(resp, pbResp); err != nil {
+		return nil, err
+	}
+	return pbResp, nil
+}
+
+func (s *grpcTransportServer) SnapshotRecovery(ctx stdcontext.Context, pbReq *protobuf.SnapshotRecoveryRequest) (*protobuf.SnapshotRecoveryResponse, error) {
+	req := &SnapshotRecoveryRequest{}
+	if err := fromProto(pbReq, req); err != nil {
+		return nil, err
+	}
+
+	resp := s.server.SnapshotRecoveryRequest(req)
+	if resp == nil {
+		return nil, fmt.Errorf("transporter: failed creating SnapshotRecovery response")
+	}
+
+	pbResp := &protobuf.SnapshotRecoveryResponse{}
+	if err := toProto(resp, pbResp); err != nil {
+		return nil, err
+	}
+	return pbResp, nil
+}
@@ -2,12 +2,16 @@ package raft
 
 import (
 	"bytes"
+	stdcontext "context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -29,9 +33,63 @@ type HTTPTransporter struct {
 	requestVotePath      string
 	snapshotPath         string
 	snapshotRecoveryPath string
+	proxyForwardPath     string
+	snapshotChunkPath    string
 	httpClient           http.Client
 	Transport            *http.Transport
 	RoundTripper         http.RoundTripper
+
+	// MaxInflightPerPeer bounds how many AppendEntries requests may be
+	// outstanding at once to a single peer. Defaults to
+	// DefaultMaxInflightPerPeer when left at zero.
+	MaxInflightPerPeer int
+
+	// BatchWindow is how long AppendEntries requests to the same peer are
+	// held so entries generated close together can be coalesced into one
+	// request. Defaults to DefaultBatchWindow when left at zero.
+	BatchWindow time.Duration
+
+	// MaxBatchBytes caps the total size of entry commands coalesced into a
+	// single AppendEntries request. Defaults to DefaultMaxBatchBytes when
+	// left at zero.
+	MaxBatchBytes int
+
+	// ProxyHandler applies a client command once it reaches the leader via
+	// the /proxy/forward endpoint. Required for proxy/standby nodes to be
+	// able to relay client requests; leave nil if the deployment doesn't use
+	// proxy nodes.
+	ProxyHandler ProxyHandler
+
+	// ReadTimeout bounds how long an incoming RPC handler (AppendEntries,
+	// RequestVote, Snapshot, SnapshotRecovery, snapshot chunk) waits to
+	// finish decoding a request body. Zero disables the deadline. The stats
+	// and proxy-forward handlers do not apply it.
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds how long an incoming RPC handler waits to finish
+	// encoding a response. Zero disables the deadline. Tracked independently
+	// of ReadTimeout so a slow read doesn't eat into the write budget. The
+	// stats and proxy-forward handlers do not apply it.
+	WriteTimeout time.Duration
+
+	pipelinesMutex sync.Mutex
+	pipelines      map[string]*appendEntriesPipeline
+
+	statsMutex sync.Mutex
+	stats      map[string]*PeerStats
+	statsPath  string
+
+	// SnapshotChunkSize is how large a chunk SendSnapshotChunks slices the
+	// snapshot reader into. Defaults to DefaultSnapshotChunkSize when left
+	// at zero.
+	SnapshotChunkSize int
+
+	// SnapshotTempDir is where incoming snapshot chunks are buffered until
+	// the transfer completes. Defaults to os.TempDir() when empty.
+	SnapshotTempDir string
+
+	transfersMutex sync.Mutex
+	transfers      map[string]*snapshotChunkTransfer
 }
 
 type HTTPMuxer interface {
@@ -54,19 +112,68 @@ func NewHTTPTransporter(prefix string, timeout time.Duration) *HTTPTransporter {
 		requestVotePath:      joinPath(prefix, "/requestVote"),
 		snapshotPath:         joinPath(prefix, "/snapshot"),
 		snapshotRecoveryPath: joinPath(prefix, "/snapshotRecovery"),
+		proxyForwardPath:     joinPath(prefix, "/proxy/forward"),
+		snapshotChunkPath:    joinPath(prefix, "/snapshotChunk"),
+		statsPath:            joinPath(prefix, "/stats"),
+		stats:                make(map[string]*PeerStats),
 		Transport: &http.Transport{
 			Dial: (&net.Dialer{
 				Timeout:   30 * time.Second,
 				KeepAlive: 30 * time.Second,
 			}).Dial,
 		},
+		pipelines: make(map[string]*appendEntriesPipeline),
+		transfers: make(map[string]*snapshotChunkTransfer),
 	}
 	t.httpClient.Transport = t.Transport
 	t.Transport.ResponseHeaderTimeout = timeout
+	t.Transport.DisableKeepAlives = t.DisableKeepAlives
+	t.Transport.ForceAttemptHTTP2 = true
 	t.RoundTripper = t.Transport
 	return t
 }
 
+// maxInflightPerPeer returns MaxInflightPerPeer, or DefaultMaxInflightPerPeer
+// if it hasn't been set.
+func (t *HTTPTransporter) maxInflightPerPeer() int {
+	if t.MaxInflightPerPeer <= 0 {
+		return DefaultMaxInflightPerPeer
+	}
+	return t.MaxInflightPerPeer
+}
+
+// batchWindow returns BatchWindow, or DefaultBatchWindow if it hasn't been
+// set.
+func (t *HTTPTransporter) batchWindow() time.Duration {
+	if t.BatchWindow <= 0 {
+		return DefaultBatchWindow
+	}
+	return t.BatchWindow
+}
+
+// maxBatchBytes returns MaxBatchBytes, or DefaultMaxBatchBytes if it hasn't
+// been set.
+func (t *HTTPTransporter) maxBatchBytes() int {
+	if t.MaxBatchBytes <= 0 {
+		return DefaultMaxBatchBytes
+	}
+	return t.MaxBatchBytes
+}
+
+// pipelineFor returns the AppendEntries pipeline for peer, creating it on
+// first use.
+func (t *HTTPTransporter) pipelineFor(peer *Peer) *appendEntriesPipeline {
+	t.pipelinesMutex.Lock()
+	defer t.pipelinesMutex.Unlock()
+
+	p, ok := t.pipelines[peer.ConnectionString]
+	if !ok {
+		p = newAppendEntriesPipeline(t, peer)
+		t.pipelines[peer.ConnectionString] = p
+	}
+	return p
+}
+
 //------------------------------------------------------------------------------
 //
 // Accessors
@@ -98,6 +205,37 @@ func (t *HTTPTransporter) SnapshotRecoveryPath() string {
 	return t.snapshotRecoveryPath
 }
 
+// Retrieves the proxy forward path.
+func (t *HTTPTransporter) ProxyForwardPath() string {
+	return t.proxyForwardPath
+}
+
+// Retrieves the stats path.
+func (t *HTTPTransporter) StatsPath() string {
+	return t.statsPath
+}
+
+// Retrieves the snapshot chunk path.
+func (t *HTTPTransporter) SnapshotChunkPath() string {
+	return t.snapshotChunkPath
+}
+
+// Stats returns a point-in-time copy of the per-peer RPC stats this
+// transporter has recorded, keyed by connection string. It's the same data
+// served as JSON from StatsPath, exposed directly for callers that want to
+// read it in-process, e.g. a ClusterConfigMonitor deciding which peer has
+// gone stale.
+func (t *HTTPTransporter) Stats() map[string]*PeerStats {
+	t.statsMutex.Lock()
+	defer t.statsMutex.Unlock()
+
+	peers := make(map[string]*PeerStats, len(t.stats))
+	for connectionString, stats := range t.stats {
+		peers[connectionString] = stats.snapshot()
+	}
+	return peers
+}
+
 //------------------------------------------------------------------------------
 //
 // Methods
@@ -115,14 +253,36 @@ func (t *HTTPTransporter) Install(server Server, mux HTTPMuxer) {
 	mux.HandleFunc(t.RequestVotePath(), t.requestVoteHandler(server))
 	mux.HandleFunc(t.SnapshotPath(), t.snapshotHandler(server))
 	mux.HandleFunc(t.SnapshotRecoveryPath(), t.snapshotRecoveryHandler(server))
+	mux.HandleFunc(t.ProxyForwardPath(), t.proxyForwardHandler(server))
+	mux.HandleFunc(t.StatsPath(), t.statsHandler(server))
+	mux.HandleFunc(t.SnapshotChunkPath(), t.snapshotChunkHandler(server))
 }
 
 //--------------------------------------
 // Outgoing
 //--------------------------------------
 
-// Sends an AppendEntries RPC to a peer.
+// Sends an AppendEntries RPC to a peer. The request is queued on the peer's
+// pipeline so entries generated within a short window can be coalesced into
+// a single request, and so at most MaxInflightPerPeer requests are ever
+// outstanding to this peer at once.
 func (t *HTTPTransporter) SendAppendEntriesRequest(server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse {
+	return t.SendAppendEntriesRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendAppendEntriesRequestContext is SendAppendEntriesRequest with a
+// context, so a caller can cancel in-flight replication early, e.g. when the
+// follower is removed, leadership is lost, or a snapshot supersedes it.
+func (t *HTTPTransporter) SendAppendEntriesRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse {
+	return t.pipelineFor(peer).send(ctx, req)
+}
+
+// sendAppendEntries performs the actual HTTP round trip for a (possibly
+// merged) AppendEntries request, tagging it with reqID via the
+// X-Raft-Request-Id header so logs on either side can be correlated. Keep-
+// alive connections (or HTTP/2 multiplexing, when the peer supports it) mean
+// this doesn't pay a fresh handshake per call.
+func (t *HTTPTransporter) sendAppendEntries(ctx stdcontext.Context, peer *Peer, req *AppendEntriesRequest, reqID uint64) *AppendEntriesResponse {
 	var b bytes.Buffer
 	var local_req *http.Request
 	var httpResp *http.Response
@@ -134,25 +294,33 @@ func (t *HTTPTransporter) SendAppendEntriesRequest(server Server, peer *Peer, re
 	}
 
 	url := joinPath(peer.ConnectionString, t.AppendEntriesPath())
-	traceln(server.Name(), "POST", url)
+	traceln("POST", url, "request-id", reqID)
 
-	local_req, err = http.NewRequest("POST", url, &b)
+	local_req, err = http.NewRequestWithContext(ctx, "POST", url, &b)
 	if err != nil {
 		traceln("transporter.ae.newrequest.error:", err)
 		return nil
 	}
 
-	local_req.Close = true
+	local_req.Close = t.DisableKeepAlives
 	local_req.Header.Add("Content-Type", "application/protobuf")
-
-	if httpResp, err = t.RoundTripper.RoundTrip(local_req); err != nil || httpResp == nil {
+	local_req.Header.Add("X-Raft-Request-Id", strconv.FormatUint(reqID, 10))
+
+	sentBytes := b.Len()
+	start := time.Now()
+	httpResp, err = t.RoundTripper.RoundTrip(local_req)
+	latency := time.Since(start)
+	if err != nil || httpResp == nil {
+		t.statsFor(peer).recordAppendEntries(latency, sentBytes, 0, err)
 		traceln("transporter.ae.response.error:", err)
 		return nil
 	}
 	defer httpResp.Body.Close()
 
 	resp := &AppendEntriesResponse{}
-	if _, err = resp.Decode(httpResp.Body); err != nil && err != io.EOF {
+	receivedBytes, err := resp.Decode(httpResp.Body)
+	t.statsFor(peer).recordAppendEntries(latency, sentBytes, int(receivedBytes), err)
+	if err != nil && err != io.EOF {
 		traceln("transporter.ae.decoding.error:", err)
 		return nil
 	}
@@ -162,6 +330,13 @@ func (t *HTTPTransporter) SendAppendEntriesRequest(server Server, peer *Peer, re
 
 // Sends a RequestVote RPC to a peer.
 func (t *HTTPTransporter) SendVoteRequest(server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse {
+	return t.SendVoteRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendVoteRequestContext is SendVoteRequest with a context, so a caller can
+// cancel an in-flight vote request early, e.g. when the election it belongs
+// to has already ended.
+func (t *HTTPTransporter) SendVoteRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse {
 	var b bytes.Buffer
 	var local_req *http.Request
 	var httpResp *http.Response
@@ -175,7 +350,7 @@ func (t *HTTPTransporter) SendVoteRequest(server Server, peer *Peer, req *Reques
 	url := fmt.Sprintf("%s%s", peer.ConnectionString, t.RequestVotePath())
 	traceln(server.Name(), "POST", url)
 
-	local_req, err = http.NewRequest("POST", url, &b)
+	local_req, err = http.NewRequestWithContext(ctx, "POST", url, &b)
 	if err != nil {
 		traceln("transporter.rv.newrequest.error:", err)
 		return nil
@@ -184,14 +359,21 @@ func (t *HTTPTransporter) SendVoteRequest(server Server, peer *Peer, req *Reques
 	local_req.Close = true
 	local_req.Header.Add("Content-Type", "application/protobuf")
 
-	if httpResp, err = t.RoundTripper.RoundTrip(local_req); err != nil || httpResp == nil {
+	sentBytes := b.Len()
+	start := time.Now()
+	httpResp, err = t.RoundTripper.RoundTrip(local_req)
+	latency := time.Since(start)
+	if err != nil || httpResp == nil {
+		t.statsFor(peer).recordVote(latency, sentBytes, 0, err)
 		traceln("transporter.rv.response.error:", err)
 		return nil
 	}
 	defer httpResp.Body.Close()
 
 	resp := &RequestVoteResponse{}
-	if _, err = resp.Decode(httpResp.Body); err != nil && err != io.EOF {
+	receivedBytes, err := resp.Decode(httpResp.Body)
+	t.statsFor(peer).recordVote(latency, sentBytes, int(receivedBytes), err)
+	if err != nil && err != io.EOF {
 		traceln("transporter.rv.decoding.error:", err)
 		return nil
 	}
@@ -210,6 +392,12 @@ func joinPath(connectionString, thePath string) string {
 
 // Sends a SnapshotRequest RPC to a peer.
 func (t *HTTPTransporter) SendSnapshotRequest(server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse {
+	return t.SendSnapshotRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendSnapshotRequestContext is SendSnapshotRequest with a context, so a
+// caller can cancel an in-flight snapshot negotiation early.
+func (t *HTTPTransporter) SendSnapshotRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse {
 	var b bytes.Buffer
 	var local_req *http.Request
 	var httpResp *http.Response
@@ -223,7 +411,7 @@ func (t *HTTPTransporter) SendSnapshotRequest(server Server, peer *Peer, req *Sn
 	url := joinPath(peer.ConnectionString, t.snapshotPath)
 	traceln(server.Name(), "POST", url)
 
-	local_req, err = http.NewRequest("POST", url, &b)
+	local_req, err = http.NewRequestWithContext(ctx, "POST", url, &b)
 	if err != nil {
 		traceln("transporter.rv.newrequest.error:", err)
 		return nil
@@ -232,14 +420,21 @@ func (t *HTTPTransporter) SendSnapshotRequest(server Server, peer *Peer, req *Sn
 	local_req.Close = true
 	local_req.Header.Add("Content-Type", "application/protobuf")
 
-	if httpResp, err = t.RoundTripper.RoundTrip(local_req); err != nil || httpResp == nil {
+	sentBytes := b.Len()
+	start := time.Now()
+	httpResp, err = t.RoundTripper.RoundTrip(local_req)
+	latency := time.Since(start)
+	if err != nil || httpResp == nil {
+		t.statsFor(peer).recordSnapshot(latency, sentBytes, 0, err)
 		traceln("transporter.rv.response.error:", err)
 		return nil
 	}
 	defer httpResp.Body.Close()
 
 	resp := &SnapshotResponse{}
-	if _, err = resp.Decode(httpResp.Body); err != nil && err != io.EOF {
+	receivedBytes, err := resp.Decode(httpResp.Body)
+	t.statsFor(peer).recordSnapshot(latency, sentBytes, int(receivedBytes), err)
+	if err != nil && err != io.EOF {
 		traceln("transporter.rv.decoding.error:", err)
 		return nil
 	}
@@ -247,8 +442,14 @@ func (t *HTTPTransporter) SendSnapshotRequest(server Server, peer *Peer, req *Sn
 	return resp
 }
 
-// Sends a SnapshotRequest RPC to a peer.
+// Sends a SnapshotRecoveryRequest RPC to a peer.
 func (t *HTTPTransporter) SendSnapshotRecoveryRequest(server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse {
+	return t.SendSnapshotRecoveryRequestContext(stdcontext.Background(), server, peer, req)
+}
+
+// SendSnapshotRecoveryRequestContext is SendSnapshotRecoveryRequest with a
+// context, so a caller can abort a stale recovery transfer early.
+func (t *HTTPTransporter) SendSnapshotRecoveryRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse {
 	var b bytes.Buffer
 	var local_req *http.Request
 	var httpResp *http.Response
@@ -262,7 +463,7 @@ func (t *HTTPTransporter) SendSnapshotRecoveryRequest(server Server, peer *Peer,
 	url := joinPath(peer.ConnectionString, t.snapshotRecoveryPath)
 	traceln(server.Name(), "POST", url)
 
-	local_req, err = http.NewRequest("POST", url, &b)
+	local_req, err = http.NewRequestWithContext(ctx, "POST", url, &b)
 	if err != nil {
 		traceln("transporter.rv.newrequest.error:", err)
 		return nil
@@ -271,14 +472,21 @@ func (t *HTTPTransporter) SendSnapshotRecoveryRequest(server Server, peer *Peer,
 	local_req.Close = true
 	local_req.Header.Add("Content-Type", "application/protobuf")
 
-	if httpResp, err = t.RoundTripper.RoundTrip(local_req); err != nil || httpResp == nil {
+	sentBytes := b.Len()
+	start := time.Now()
+	httpResp, err = t.RoundTripper.RoundTrip(local_req)
+	latency := time.Since(start)
+	if err != nil || httpResp == nil {
+		t.statsFor(peer).recordSnapshot(latency, sentBytes, 0, err)
 		traceln("transporter.rv.response.error:", err)
 		return nil
 	}
 	defer httpResp.Body.Close()
 
 	resp := &SnapshotRecoveryResponse{}
-	if _, err = resp.Decode(httpResp.Body); err != nil && err != io.EOF {
+	receivedBytes, err := resp.Decode(httpResp.Body)
+	t.statsFor(peer).recordSnapshot(latency, sentBytes, int(receivedBytes), err)
+	if err != nil && err != io.EOF {
 		traceln("transporter.rv.decoding.error:", err)
 		return nil
 	}
@@ -293,10 +501,10 @@ func (t *HTTPTransporter) SendSnapshotRecoveryRequest(server Server, peer *Peer,
 // Handles incoming AppendEntries requests.
 func (t *HTTPTransporter) appendEntriesHandler(server Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		traceln(server.Name(), "RECV /appendEntries")
+		traceln(server.Name(), "RECV /appendEntries", "request-id", r.Header.Get("X-Raft-Request-Id"))
 
 		req := &AppendEntriesRequest{}
-		if _, err := req.Decode(r.Body); err != nil {
+		if err := decodeWithDeadline(req, r, w, t.ReadTimeout); err != nil {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
@@ -315,7 +523,7 @@ func (t *HTTPTransporter) appendEntriesHandler(server Server) http.HandlerFunc {
 			http.Error(w, "Failed creating response.", http.StatusInternalServerError)
 			return
 		}
-		if _, err := resp.Encode(w); err != nil {
+		if err := encodeWithDeadline(resp, w, t.WriteTimeout); err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
@@ -328,7 +536,7 @@ func (t *HTTPTransporter) requestVoteHandler(server Server) http.HandlerFunc {
 		traceln(server.Name(), "RECV /requestVote")
 
 		req := &RequestVoteRequest{}
-		if _, err := req.Decode(r.Body); err != nil {
+		if err := decodeWithDeadline(req, r, w, t.ReadTimeout); err != nil {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
@@ -338,7 +546,7 @@ func (t *HTTPTransporter) requestVoteHandler(server Server) http.HandlerFunc {
 			http.Error(w, "Failed creating response.", http.StatusInternalServerError)
 			return
 		}
-		if _, err := resp.Encode(w); err != nil {
+		if err := encodeWithDeadline(resp, w, t.WriteTimeout); err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
@@ -351,7 +559,7 @@ func (t *HTTPTransporter) snapshotHandler(server Server) http.HandlerFunc {
 		traceln(server.Name(), "RECV /snapshot")
 
 		req := &SnapshotRequest{}
-		if _, err := req.Decode(r.Body); err != nil {
+		if err := decodeWithDeadline(req, r, w, t.ReadTimeout); err != nil {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
@@ -361,7 +569,7 @@ func (t *HTTPTransporter) snapshotHandler(server Server) http.HandlerFunc {
 			http.Error(w, "Failed creating response.", http.StatusInternalServerError)
 			return
 		}
-		if _, err := resp.Encode(w); err != nil {
+		if err := encodeWithDeadline(resp, w, t.WriteTimeout); err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
@@ -374,7 +582,7 @@ func (t *HTTPTransporter) snapshotRecoveryHandler(server Server) http.HandlerFun
 		traceln(server.Name(), "RECV /snapshotRecovery")
 
 		req := &SnapshotRecoveryRequest{}
-		if _, err := req.Decode(r.Body); err != nil {
+		if err := decodeWithDeadline(req, r, w, t.ReadTimeout); err != nil {
 			http.Error(w, "", http.StatusBadRequest)
 			return
 		}
@@ -384,7 +592,26 @@ func (t *HTTPTransporter) snapshotRecoveryHandler(server Server) http.HandlerFun
 			http.Error(w, "Failed creating response.", http.StatusInternalServerError)
 			return
 		}
-		if _, err := resp.Encode(w); err != nil {
+		if err := encodeWithDeadline(resp, w, t.WriteTimeout); err != nil {
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// Handles incoming stats requests, returning the leader and per-peer RPC
+// stats as JSON.
+func (t *HTTPTransporter) statsHandler(server Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		traceln(server.Name(), "RECV /stats")
+
+		clusterStats := &ClusterStats{
+			Leader: server.Leader(),
+			Peers:  t.Stats(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(clusterStats); err != nil {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
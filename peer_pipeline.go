@@ -0,0 +1,186 @@
+package raft
+
+import (
+	stdcontext "context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shelmesky/raft/protobuf"
+)
+
+// Defaults used when an HTTPTransporter field is left at its zero value.
+const (
+	DefaultMaxInflightPerPeer = 4
+	DefaultBatchWindow        = 2 * time.Millisecond
+	DefaultMaxBatchBytes      = 4 << 20 // 4MB
+)
+
+// appendEntriesPipeline pipelines AppendEntries RPCs to a single peer: up to
+// MaxInflightPerPeer requests may be outstanding at once over the
+// transporter's keep-alive connections. A request that finds a free slot is
+// sent immediately, with no added latency. Only once every slot is already
+// busy does a request wait out BatchWindow, coalescing whatever else shows
+// up for this peer in the meantime into one merged request rather than
+// queuing each one to wait its own turn serially. That's also the one case
+// where holding a request actually pays for itself: it was going to wait
+// for a slot regardless, so the wait is spent accumulating a bigger batch
+// instead of sitting idle.
+//
+// A caller that never has more than MaxInflightPerPeer requests outstanding
+// per peer at once (e.g. the replication loop calling this synchronously,
+// one request at a time) will always find a free slot and never pay the
+// BatchWindow delay at all.
+type appendEntriesPipeline struct {
+	transporter *HTTPTransporter
+	peer        *Peer
+
+	sem    chan struct{} // bounds concurrent in-flight requests
+	nextID uint64        // monotonic, carried in the X-Raft-Request-Id header
+
+	mu      sync.Mutex
+	pending []*pendingAppendEntries
+	timer   *time.Timer
+}
+
+// pendingAppendEntries is one caller's request sitting in the batch queue,
+// waiting to be coalesced with its neighbors and flushed.
+type pendingAppendEntries struct {
+	ctx  stdcontext.Context
+	req  *AppendEntriesRequest
+	done chan *AppendEntriesResponse
+}
+
+func newAppendEntriesPipeline(t *HTTPTransporter, peer *Peer) *appendEntriesPipeline {
+	return &appendEntriesPipeline{
+		transporter: t,
+		peer:        peer,
+		sem:         make(chan struct{}, t.maxInflightPerPeer()),
+	}
+}
+
+// send either dispatches req immediately, if a slot is free, or queues it
+// for batching and blocks until the (possibly merged) batch it ends up in
+// has a response, or ctx is canceled first.
+func (p *appendEntriesPipeline) send(ctx stdcontext.Context, req *AppendEntriesRequest) *AppendEntriesResponse {
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+		reqID := atomic.AddUint64(&p.nextID, 1)
+		return p.transporter.sendAppendEntries(ctx, p.peer, req, reqID)
+	default:
+	}
+
+	pending := &pendingAppendEntries{ctx: ctx, req: req, done: make(chan *AppendEntriesResponse, 1)}
+
+	p.mu.Lock()
+	p.pending = append(p.pending, pending)
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.transporter.batchWindow(), p.flush)
+	}
+	p.mu.Unlock()
+
+	select {
+	case resp := <-pending.done:
+		return resp
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// flush takes whatever has queued up during the batch window, merges it into
+// as few requests as MaxBatchBytes allows, and sends each one, respecting
+// MaxInflightPerPeer.
+func (p *appendEntriesPipeline) flush() {
+	p.mu.Lock()
+	batch := p.pending
+	p.pending = nil
+	p.timer = nil
+	p.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	for _, group := range p.coalesce(batch) {
+		p.sem <- struct{}{}
+		go func(group []*pendingAppendEntries) {
+			defer func() { <-p.sem }()
+			p.sendGroup(group)
+		}(group)
+	}
+}
+
+// coalesce splits batch into groups that can each be sent as a single merged
+// AppendEntriesRequest without the combined entries exceeding MaxBatchBytes.
+func (p *appendEntriesPipeline) coalesce(batch []*pendingAppendEntries) [][]*pendingAppendEntries {
+	maxBytes := p.transporter.maxBatchBytes()
+
+	var groups [][]*pendingAppendEntries
+	var current []*pendingAppendEntries
+	size := 0
+
+	for _, item := range batch {
+		itemSize := entriesSize(item.req.Entries)
+		if len(current) > 0 && size+itemSize > maxBytes {
+			groups = append(groups, current)
+			current = nil
+			size = 0
+		}
+		current = append(current, item)
+		size += itemSize
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// sendGroup merges a group's requests into one AppendEntriesRequest, sends
+// it, and fans the single response back out to every waiter in the group.
+// The network call uses the first (oldest) request's context, since that's
+// the one most likely to have already been canceled if this peer is going
+// away.
+func (p *appendEntriesPipeline) sendGroup(group []*pendingAppendEntries) {
+	req := mergeAppendEntriesRequests(group)
+	reqID := atomic.AddUint64(&p.nextID, 1)
+
+	resp := p.transporter.sendAppendEntries(group[0].ctx, p.peer, req, reqID)
+	for _, item := range group {
+		item.done <- resp
+	}
+}
+
+// mergeAppendEntriesRequests concatenates the Entries of a batch of requests
+// for the same peer in arrival order. The leader/term/commit-index fields of
+// the last request win since they reflect the most recent leader state; the
+// first request's PrevLogIndex/PrevLogTerm is kept since entries must still
+// append contiguously from there.
+func mergeAppendEntriesRequests(group []*pendingAppendEntries) *AppendEntriesRequest {
+	if len(group) == 1 {
+		return group[0].req
+	}
+
+	first := group[0].req
+	last := group[len(group)-1].req
+
+	merged := &AppendEntriesRequest{
+		Term:         last.Term,
+		PrevLogIndex: first.PrevLogIndex,
+		PrevLogTerm:  first.PrevLogTerm,
+		CommitIndex:  last.CommitIndex,
+		LeaderName:   last.LeaderName,
+	}
+	for _, item := range group {
+		merged.Entries = append(merged.Entries, item.req.Entries...)
+	}
+	return merged
+}
+
+func entriesSize(entries []*protobuf.LogEntry) int {
+	size := 0
+	for _, entry := range entries {
+		size += len(entry.Command)
+	}
+	return size
+}
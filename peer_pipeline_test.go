@@ -0,0 +1,79 @@
+package raft
+
+import (
+	"testing"
+
+	"github.com/shelmesky/raft/protobuf"
+)
+
+func TestEntriesSizeSumsCommandBytes(t *testing.T) {
+	entries := []*protobuf.LogEntry{
+		{Command: []byte("abc")},
+		{Command: []byte("de")},
+	}
+	if got := entriesSize(entries); got != 5 {
+		t.Errorf("entriesSize() = %d, want 5", got)
+	}
+}
+
+func TestMergeAppendEntriesRequestsConcatenatesInOrderAndKeepsFirstPrevLog(t *testing.T) {
+	first := &AppendEntriesRequest{
+		Term:         3,
+		PrevLogIndex: 10,
+		PrevLogTerm:  2,
+		CommitIndex:  10,
+		LeaderName:   "leader1",
+		Entries:      []*protobuf.LogEntry{{Command: []byte("a")}},
+	}
+	last := &AppendEntriesRequest{
+		Term:         4,
+		PrevLogIndex: 11,
+		PrevLogTerm:  3,
+		CommitIndex:  12,
+		LeaderName:   "leader1",
+		Entries:      []*protobuf.LogEntry{{Command: []byte("b")}, {Command: []byte("c")}},
+	}
+
+	group := []*pendingAppendEntries{{req: first}, {req: last}}
+	merged := mergeAppendEntriesRequests(group)
+
+	if merged.Term != 4 || merged.CommitIndex != 12 {
+		t.Errorf("merged Term/CommitIndex = %d/%d, want the last request's values (4/12)", merged.Term, merged.CommitIndex)
+	}
+	if merged.PrevLogIndex != 10 || merged.PrevLogTerm != 2 {
+		t.Errorf("merged PrevLogIndex/PrevLogTerm = %d/%d, want the first request's values (10/2)", merged.PrevLogIndex, merged.PrevLogTerm)
+	}
+	if len(merged.Entries) != 3 {
+		t.Fatalf("merged Entries has %d entries, want 3", len(merged.Entries))
+	}
+	if string(merged.Entries[0].Command) != "a" || string(merged.Entries[1].Command) != "b" || string(merged.Entries[2].Command) != "c" {
+		t.Errorf("merged Entries out of order: %v", merged.Entries)
+	}
+}
+
+func TestMergeAppendEntriesRequestsSingleGroupReturnsOriginal(t *testing.T) {
+	req := &AppendEntriesRequest{Term: 1}
+	merged := mergeAppendEntriesRequests([]*pendingAppendEntries{{req: req}})
+	if merged != req {
+		t.Error("merging a single-item group should return the original request unchanged")
+	}
+}
+
+func TestCoalesceSplitsOnMaxBatchBytes(t *testing.T) {
+	t1 := &HTTPTransporter{MaxBatchBytes: 5}
+	p := &appendEntriesPipeline{transporter: t1}
+
+	batch := []*pendingAppendEntries{
+		{req: &AppendEntriesRequest{Entries: []*protobuf.LogEntry{{Command: []byte("abc")}}}},
+		{req: &AppendEntriesRequest{Entries: []*protobuf.LogEntry{{Command: []byte("de")}}}},
+		{req: &AppendEntriesRequest{Entries: []*protobuf.LogEntry{{Command: []byte("fghij")}}}},
+	}
+
+	groups := p.coalesce(batch)
+	if len(groups) != 2 {
+		t.Fatalf("coalesce() returned %d groups, want 2 (first two requests fit in 5 bytes, the third needs its own group)", len(groups))
+	}
+	if len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Errorf("coalesce() groups = %v, want [[req1 req2] [req3]]", groups)
+	}
+}
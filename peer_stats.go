@@ -0,0 +1,112 @@
+package raft
+
+import (
+	"sync"
+	"time"
+)
+
+// RPCStats records latency, throughput and failure counters for one kind of
+// RPC (AppendEntries/Vote/Snapshot) sent to a peer.
+type RPCStats struct {
+	MinLatency     time.Duration `json:"minLatency"`
+	MaxLatency     time.Duration `json:"maxLatency"`
+	AvgLatency     time.Duration `json:"avgLatency"`
+	CurrentLatency time.Duration `json:"currentLatency"`
+	BytesSent      uint64        `json:"bytesSent"`
+	BytesReceived  uint64        `json:"bytesReceived"`
+	SuccessCount   uint64        `json:"successCount"`
+	FailureCount   uint64        `json:"failureCount"`
+	LastContact    time.Time     `json:"lastContact"`
+}
+
+func (s *RPCStats) record(latency time.Duration, bytesSent, bytesReceived int, err error) {
+	s.CurrentLatency = latency
+	if s.MinLatency == 0 || latency < s.MinLatency {
+		s.MinLatency = latency
+	}
+	if latency > s.MaxLatency {
+		s.MaxLatency = latency
+	}
+	if s.SuccessCount+s.FailureCount == 0 {
+		s.AvgLatency = latency
+	} else {
+		total := s.SuccessCount + s.FailureCount
+		s.AvgLatency = (s.AvgLatency*time.Duration(total) + latency) / time.Duration(total+1)
+	}
+
+	s.BytesSent += uint64(bytesSent)
+	s.BytesReceived += uint64(bytesReceived)
+
+	if err == nil {
+		s.SuccessCount++
+		s.LastContact = time.Now()
+	} else {
+		s.FailureCount++
+	}
+}
+
+// PeerStats aggregates RPCStats for every RPC kind sent to a single peer.
+// HTTPTransporter keeps one of these per peer, updated on every RoundTrip in
+// its four Send* methods.
+type PeerStats struct {
+	mutex            sync.Mutex
+	ConnectionString string   `json:"connectionString"`
+	AppendEntries    RPCStats `json:"appendEntries"`
+	Vote             RPCStats `json:"vote"`
+	Snapshot         RPCStats `json:"snapshot"`
+}
+
+func newPeerStats(connectionString string) *PeerStats {
+	return &PeerStats{ConnectionString: connectionString}
+}
+
+func (p *PeerStats) recordAppendEntries(latency time.Duration, bytesSent, bytesReceived int, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.AppendEntries.record(latency, bytesSent, bytesReceived, err)
+}
+
+func (p *PeerStats) recordVote(latency time.Duration, bytesSent, bytesReceived int, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Vote.record(latency, bytesSent, bytesReceived, err)
+}
+
+func (p *PeerStats) recordSnapshot(latency time.Duration, bytesSent, bytesReceived int, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Snapshot.record(latency, bytesSent, bytesReceived, err)
+}
+
+// snapshot returns a copy of p safe to marshal as JSON without holding the
+// lock for the duration of the encode.
+func (p *PeerStats) snapshot() *PeerStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return &PeerStats{
+		ConnectionString: p.ConnectionString,
+		AppendEntries:    p.AppendEntries,
+		Vote:             p.Vote,
+		Snapshot:         p.Snapshot,
+	}
+}
+
+// statsFor returns the PeerStats for peer, creating it on first use.
+func (t *HTTPTransporter) statsFor(peer *Peer) *PeerStats {
+	t.statsMutex.Lock()
+	defer t.statsMutex.Unlock()
+
+	stats, ok := t.stats[peer.ConnectionString]
+	if !ok {
+		stats = newPeerStats(peer.ConnectionString)
+		t.stats[peer.ConnectionString] = stats
+	}
+	return stats
+}
+
+// ClusterStats is the payload served from GET {prefix}/stats: the leader's
+// name and every known peer's stats.
+type ClusterStats struct {
+	Leader string                `json:"leader"`
+	Peers  map[string]*PeerStats `json:"peers"`
+}
@@ -0,0 +1,60 @@
+package raft
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRPCStatsRecordTracksLatencyAndCounts(t *testing.T) {
+	var s RPCStats
+
+	s.record(10*time.Millisecond, 100, 200, nil)
+	s.record(30*time.Millisecond, 50, 60, nil)
+	s.record(20*time.Millisecond, 0, 0, errors.New("boom"))
+
+	if s.MinLatency != 10*time.Millisecond {
+		t.Errorf("MinLatency = %v, want 10ms", s.MinLatency)
+	}
+	if s.MaxLatency != 30*time.Millisecond {
+		t.Errorf("MaxLatency = %v, want 30ms", s.MaxLatency)
+	}
+	if s.SuccessCount != 2 {
+		t.Errorf("SuccessCount = %d, want 2", s.SuccessCount)
+	}
+	if s.FailureCount != 1 {
+		t.Errorf("FailureCount = %d, want 1", s.FailureCount)
+	}
+	if s.BytesSent != 150 || s.BytesReceived != 260 {
+		t.Errorf("BytesSent/BytesReceived = %d/%d, want 150/260", s.BytesSent, s.BytesReceived)
+	}
+	if s.LastContact.IsZero() {
+		t.Error("LastContact was not set after a successful call")
+	}
+}
+
+func TestRPCStatsRecordFailureDoesNotAdvanceLastContact(t *testing.T) {
+	var s RPCStats
+	s.record(5*time.Millisecond, 1, 1, nil)
+	want := s.LastContact
+
+	s.record(5*time.Millisecond, 1, 1, errors.New("boom"))
+	if s.LastContact != want {
+		t.Errorf("LastContact changed on a failed call: got %v, want %v", s.LastContact, want)
+	}
+}
+
+func TestPeerStatsSnapshotIsIndependentCopy(t *testing.T) {
+	p := newPeerStats("peer1")
+	p.recordAppendEntries(1*time.Millisecond, 1, 1, nil)
+
+	snap := p.snapshot()
+	p.recordAppendEntries(2*time.Millisecond, 1, 1, nil)
+
+	if snap.AppendEntries.SuccessCount != 1 {
+		t.Errorf("snapshot SuccessCount = %d, want 1 (should not see the later record)", snap.AppendEntries.SuccessCount)
+	}
+	if snap.ConnectionString != "peer1" {
+		t.Errorf("snapshot ConnectionString = %q, want %q", snap.ConnectionString, "peer1")
+	}
+}
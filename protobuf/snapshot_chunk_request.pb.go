@@ -0,0 +1,63 @@
+// Code generated by protoc-gen-go.
+// source: snapshot_chunk_request.proto
+// DO NOT EDIT!
+
+package protobuf
+
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = math.Inf
+
+type SnapshotChunkRequest struct {
+	SnapshotID       *string `protobuf:"bytes,1,req" json:"SnapshotID,omitempty"`
+	Offset           *uint64 `protobuf:"varint,2,req" json:"Offset,omitempty"`
+	Data             []byte  `protobuf:"bytes,3,req" json:"Data,omitempty"`
+	Done             *bool   `protobuf:"varint,4,req" json:"Done,omitempty"`
+	Crc32            *uint32 `protobuf:"varint,5,req" json:"Crc32,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *SnapshotChunkRequest) Reset()         { *m = SnapshotChunkRequest{} }
+func (m *SnapshotChunkRequest) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunkRequest) ProtoMessage()    {}
+
+func (m *SnapshotChunkRequest) GetSnapshotID() string {
+	if m != nil && m.SnapshotID != nil {
+		return *m.SnapshotID
+	}
+	return ""
+}
+
+func (m *SnapshotChunkRequest) GetOffset() uint64 {
+	if m != nil && m.Offset != nil {
+		return *m.Offset
+	}
+	return 0
+}
+
+func (m *SnapshotChunkRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *SnapshotChunkRequest) GetDone() bool {
+	if m != nil && m.Done != nil {
+		return *m.Done
+	}
+	return false
+}
+
+func (m *SnapshotChunkRequest) GetCrc32() uint32 {
+	if m != nil && m.Crc32 != nil {
+		return *m.Crc32
+	}
+	return 0
+}
+
+func init() {
+}
@@ -0,0 +1,39 @@
+// Code generated by protoc-gen-go.
+// source: snapshot_chunk_response.proto
+// DO NOT EDIT!
+
+package protobuf
+
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = math.Inf
+
+type SnapshotChunkResponse struct {
+	Success          *bool   `protobuf:"varint,1,req" json:"Success,omitempty"`
+	NextOffset       *uint64 `protobuf:"varint,2,req" json:"NextOffset,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *SnapshotChunkResponse) Reset()         { *m = SnapshotChunkResponse{} }
+func (m *SnapshotChunkResponse) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunkResponse) ProtoMessage()    {}
+
+func (m *SnapshotChunkResponse) GetSuccess() bool {
+	if m != nil && m.Success != nil {
+		return *m.Success
+	}
+	return false
+}
+
+func (m *SnapshotChunkResponse) GetNextOffset() uint64 {
+	if m != nil && m.NextOffset != nil {
+		return *m.NextOffset
+	}
+	return 0
+}
+
+func init() {
+}
@@ -0,0 +1,47 @@
+// Code generated by protoc-gen-go.
+// source: snapshot_recovery_response.proto
+// DO NOT EDIT!
+
+package protobuf
+
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = math.Inf
+
+type SnapshotRecoveryResponse struct {
+	Term             *uint64 `protobuf:"varint,1,req" json:"Term,omitempty"`
+	Success          *bool   `protobuf:"varint,2,req" json:"Success,omitempty"`
+	CommitIndex      *uint64 `protobuf:"varint,3,req" json:"CommitIndex,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *SnapshotRecoveryResponse) Reset()         { *m = SnapshotRecoveryResponse{} }
+func (m *SnapshotRecoveryResponse) String() string { return proto.CompactTextString(m) }
+func (*SnapshotRecoveryResponse) ProtoMessage()    {}
+
+func (m *SnapshotRecoveryResponse) GetTerm() uint64 {
+	if m != nil && m.Term != nil {
+		return *m.Term
+	}
+	return 0
+}
+
+func (m *SnapshotRecoveryResponse) GetSuccess() bool {
+	if m != nil && m.Success != nil {
+		return *m.Success
+	}
+	return false
+}
+
+func (m *SnapshotRecoveryResponse) GetCommitIndex() uint64 {
+	if m != nil && m.CommitIndex != nil {
+		return *m.CommitIndex
+	}
+	return 0
+}
+
+func init() {
+}
@@ -0,0 +1,78 @@
+package raft
+
+import (
+	"io"
+	"net/http"
+)
+
+// ProxyHandler applies a client command forwarded from a proxy peer once it
+// reaches the leader, returning the raw response body to send back. It is
+// set by the application embedding raft, the same way it wires up its own
+// client-facing command endpoint; the transporter itself has no notion of
+// what a command is.
+type ProxyHandler func(body io.Reader) (io.Reader, error)
+
+// proxyForwardHandler handles /proxy/forward: a proxy node that received a
+// client command it cannot itself apply re-POSTs the raw request body here,
+// to whichever server it believes is leader. If this server knows of another
+// leader, the request is forwarded again to that leader's connection
+// string; if it is the leader, or no leader is known, ProxyHandler is
+// invoked and its result streamed back.
+func (t *HTTPTransporter) proxyForwardHandler(server Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		traceln(server.Name(), "RECV /proxy/forward")
+
+		if leader := server.Leader(); leader != "" && leader != server.Name() {
+			peer, ok := server.Peers()[leader]
+			if !ok {
+				http.Error(w, "transporter: unknown leader "+leader, http.StatusBadGateway)
+				return
+			}
+			t.forwardToLeader(w, r, peer.ConnectionString)
+			return
+		}
+
+		if t.ProxyHandler == nil {
+			http.Error(w, "transporter: no ProxyHandler installed", http.StatusNotImplemented)
+			return
+		}
+
+		result, err := t.ProxyHandler(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/protobuf")
+		io.Copy(w, result)
+	}
+}
+
+// ForwardProxyRequest streams a client request body to a leader's
+// /proxy/forward endpoint and returns the raw response, so a proxy node can
+// relay the cluster's read/write path without voting.
+func (t *HTTPTransporter) ForwardProxyRequest(leaderConnectionString string, body io.Reader) (*http.Response, error) {
+	url := joinPath(leaderConnectionString, t.ProxyForwardPath())
+
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/protobuf")
+
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func (t *HTTPTransporter) forwardToLeader(w http.ResponseWriter, r *http.Request, leaderConnectionString string) {
+	resp, err := t.ForwardProxyRequest(leaderConnectionString, r.Body)
+	if err != nil {
+		traceln("transporter.proxy.forward.error:", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
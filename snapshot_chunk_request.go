@@ -0,0 +1,70 @@
+package raft
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/shelmesky/raft/protobuf"
+)
+
+// SnapshotChunkRequest represents a portion of a snapshot being streamed to
+// a follower. Offset is the byte offset Data starts at in the full
+// snapshot; Done marks the final chunk, at which point CRC32 covers the
+// whole reassembled file so the follower can verify it before installing.
+type SnapshotChunkRequest struct {
+	SnapshotID string
+	Offset     uint64
+	Data       []byte
+	Done       bool
+	CRC32      uint32
+}
+
+// NewSnapshotChunkRequest creates a new SnapshotChunkRequest.
+func NewSnapshotChunkRequest(snapshotID string, offset uint64, data []byte, done bool, crc32 uint32) *SnapshotChunkRequest {
+	return &SnapshotChunkRequest{
+		SnapshotID: snapshotID,
+		Offset:     offset,
+		Data:       data,
+		Done:       done,
+		CRC32:      crc32,
+	}
+}
+
+// Encode writes the request to w in protobuf wire format.
+func (req *SnapshotChunkRequest) Encode(w io.Writer) (int, error) {
+	pb := &protobuf.SnapshotChunkRequest{
+		SnapshotID: proto.String(req.SnapshotID),
+		Offset:     proto.Uint64(req.Offset),
+		Data:       req.Data,
+		Done:       proto.Bool(req.Done),
+		Crc32:      proto.Uint32(req.CRC32),
+	}
+	p, err := proto.Marshal(pb)
+	if err != nil {
+		return -1, err
+	}
+	return w.Write(p)
+}
+
+// Decode reads a request from r in protobuf wire format.
+func (req *SnapshotChunkRequest) Decode(r io.Reader) (int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	totalBytes := len(data)
+
+	pb := &protobuf.SnapshotChunkRequest{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return -1, err
+	}
+
+	req.SnapshotID = pb.GetSnapshotID()
+	req.Offset = pb.GetOffset()
+	req.Data = pb.GetData()
+	req.Done = pb.GetDone()
+	req.CRC32 = pb.GetCrc32()
+
+	return totalBytes, nil
+}
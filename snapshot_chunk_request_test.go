@@ -0,0 +1,28 @@
+package raft
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshotChunkRequestEncodeDecodeRoundTrip(t *testing.T) {
+	want := NewSnapshotChunkRequest("snap-1", 4096, []byte("chunk payload"), true, 0xdeadbeef)
+
+	var buf bytes.Buffer
+	if _, err := want.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	got := &SnapshotChunkRequest{}
+	if _, err := got.Decode(&buf); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if got.SnapshotID != want.SnapshotID ||
+		got.Offset != want.Offset ||
+		!bytes.Equal(got.Data, want.Data) ||
+		got.Done != want.Done ||
+		got.CRC32 != want.CRC32 {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package raft
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/shelmesky/raft/protobuf"
+)
+
+// SnapshotChunkResponse acknowledges a SnapshotChunkRequest. NextOffset is
+// the byte offset the follower expects the next chunk to start at, which
+// lets a sender resume a broken transfer instead of restarting from zero.
+type SnapshotChunkResponse struct {
+	Success    bool
+	NextOffset uint64
+}
+
+// NewSnapshotChunkResponse creates a new SnapshotChunkResponse.
+func NewSnapshotChunkResponse(success bool, nextOffset uint64) *SnapshotChunkResponse {
+	return &SnapshotChunkResponse{
+		Success:    success,
+		NextOffset: nextOffset,
+	}
+}
+
+// Encode writes the response to w in protobuf wire format.
+func (resp *SnapshotChunkResponse) Encode(w io.Writer) (int, error) {
+	pb := &protobuf.SnapshotChunkResponse{
+		Success:    proto.Bool(resp.Success),
+		NextOffset: proto.Uint64(resp.NextOffset),
+	}
+	p, err := proto.Marshal(pb)
+	if err != nil {
+		return -1, err
+	}
+	return w.Write(p)
+}
+
+// Decode reads a response from r in protobuf wire format.
+func (resp *SnapshotChunkResponse) Decode(r io.Reader) (int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	totalBytes := len(data)
+
+	pb := &protobuf.SnapshotChunkResponse{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return -1, err
+	}
+
+	resp.Success = pb.GetSuccess()
+	resp.NextOffset = pb.GetNextOffset()
+
+	return totalBytes, nil
+}
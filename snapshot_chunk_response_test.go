@@ -0,0 +1,24 @@
+package raft
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSnapshotChunkResponseEncodeDecodeRoundTrip(t *testing.T) {
+	want := NewSnapshotChunkResponse(true, 8192)
+
+	var buf bytes.Buffer
+	if _, err := want.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	got := &SnapshotChunkResponse{}
+	if _, err := got.Decode(&buf); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if got.Success != want.Success || got.NextOffset != want.NextOffset {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package raft
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultSnapshotChunkSize is used by SendSnapshotChunks when
+// HTTPTransporter.SnapshotChunkSize is left at zero.
+const DefaultSnapshotChunkSize = 1 << 20 // 1MB
+
+// snapshotInstaller is implemented by a Server that wants to receive
+// chunked snapshot transfers. It's checked with a type assertion rather
+// than added to the Server interface so servers that don't use chunked
+// transfer don't need to implement it.
+type snapshotInstaller interface {
+	InstallSnapshot(path string) error
+}
+
+// snapshotChunkTransfer tracks one in-progress incoming snapshot transfer:
+// the temp file chunks are appended to, how many bytes have landed so far,
+// and a running CRC32 over everything written, which the Done chunk's
+// checksum is checked against.
+type snapshotChunkTransfer struct {
+	mutex  sync.Mutex
+	file   *os.File
+	offset uint64
+	hash   uint32
+}
+
+//--------------------------------------
+// Incoming
+//--------------------------------------
+
+// Handles incoming snapshot chunk requests: appends the chunk to a temp
+// file, verifies its CRC32, and once a Done chunk arrives whose CRC32
+// matches the whole file's running checksum, hands the file to the server
+// to install.
+func (t *HTTPTransporter) snapshotChunkHandler(server Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		traceln(server.Name(), "RECV /snapshotChunk")
+
+		req := &SnapshotChunkRequest{}
+		if err := decodeWithDeadline(req, r, w, t.ReadTimeout); err != nil {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		nextOffset, err := t.writeSnapshotChunk(server, req)
+		if err != nil {
+			traceln("transporter.snapshotChunk.error:", err)
+			resp := NewSnapshotChunkResponse(false, nextOffset)
+			encodeWithDeadline(resp, w, t.WriteTimeout)
+			return
+		}
+
+		resp := NewSnapshotChunkResponse(true, nextOffset)
+		if err := encodeWithDeadline(resp, w, t.WriteTimeout); err != nil {
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// writeSnapshotChunk appends req's data to the transfer for req.SnapshotID,
+// creating it on first use, and installs the snapshot once the Done chunk
+// checks out. It returns the offset the next chunk is expected at.
+func (t *HTTPTransporter) writeSnapshotChunk(server Server, req *SnapshotChunkRequest) (uint64, error) {
+	transfer, err := t.transferFor(req.SnapshotID)
+	if err != nil {
+		return 0, err
+	}
+
+	transfer.mutex.Lock()
+	defer transfer.mutex.Unlock()
+
+	if req.Offset != transfer.offset {
+		// Out of order, or a retransmit of an already-applied chunk; tell
+		// the sender where we actually are so it can resume from there.
+		return transfer.offset, nil
+	}
+
+	if !req.Done && crc32.ChecksumIEEE(req.Data) != req.CRC32 {
+		return transfer.offset, fmt.Errorf("transporter: chunk CRC32 mismatch at offset %d", req.Offset)
+	}
+
+	if _, err := transfer.file.Write(req.Data); err != nil {
+		return transfer.offset, err
+	}
+	transfer.hash = crc32.Update(transfer.hash, crc32.IEEETable, req.Data)
+	transfer.offset += uint64(len(req.Data))
+
+	if !req.Done {
+		return transfer.offset, nil
+	}
+
+	// The Done chunk's CRC32 covers the whole reassembled file, not just its
+	// own Data.
+	if transfer.hash != req.CRC32 {
+		path := transfer.file.Name()
+		transfer.file.Close()
+		os.Remove(path)
+		t.forgetTransfer(req.SnapshotID)
+		return transfer.offset, fmt.Errorf("transporter: full snapshot CRC32 mismatch for %s", req.SnapshotID)
+	}
+
+	path := transfer.file.Name()
+	if err := transfer.file.Close(); err != nil {
+		t.forgetTransfer(req.SnapshotID)
+		os.Remove(path)
+		return transfer.offset, err
+	}
+	t.forgetTransfer(req.SnapshotID)
+
+	installer, ok := server.(snapshotInstaller)
+	if !ok {
+		os.Remove(path)
+		return transfer.offset, fmt.Errorf("transporter: server does not implement InstallSnapshot")
+	}
+	if err := installer.InstallSnapshot(path); err != nil {
+		os.Remove(path)
+		return transfer.offset, err
+	}
+	return transfer.offset, nil
+}
+
+func (t *HTTPTransporter) transferFor(snapshotID string) (*snapshotChunkTransfer, error) {
+	t.transfersMutex.Lock()
+	defer t.transfersMutex.Unlock()
+
+	if transfer, ok := t.transfers[snapshotID]; ok {
+		return transfer, nil
+	}
+
+	dir := t.SnapshotTempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	file, err := os.Create(filepath.Join(dir, "snapshot-"+snapshotID+".tmp"))
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &snapshotChunkTransfer{file: file}
+	t.transfers[snapshotID] = transfer
+	return transfer, nil
+}
+
+func (t *HTTPTransporter) forgetTransfer(snapshotID string) {
+	t.transfersMutex.Lock()
+	defer t.transfersMutex.Unlock()
+	delete(t.transfers, snapshotID)
+}
+
+//--------------------------------------
+// Outgoing
+//--------------------------------------
+
+// sendSnapshotChunk POSTs a single chunk and decodes the follower's
+// acknowledgement.
+func (t *HTTPTransporter) sendSnapshotChunk(peer *Peer, req *SnapshotChunkRequest) *SnapshotChunkResponse {
+	var b bytes.Buffer
+	if _, err := req.Encode(&b); err != nil {
+		traceln("transporter.snapshotChunk.encoding.error:", err)
+		return nil
+	}
+
+	url := joinPath(peer.ConnectionString, t.SnapshotChunkPath())
+	local_req, err := http.NewRequest("POST", url, &b)
+	if err != nil {
+		traceln("transporter.snapshotChunk.newrequest.error:", err)
+		return nil
+	}
+	local_req.Header.Add("Content-Type", "application/protobuf")
+
+	httpResp, err := t.RoundTripper.RoundTrip(local_req)
+	if err != nil || httpResp == nil {
+		traceln("transporter.snapshotChunk.response.error:", err)
+		return nil
+	}
+	defer httpResp.Body.Close()
+
+	resp := &SnapshotChunkResponse{}
+	if _, err := resp.Decode(httpResp.Body); err != nil && err != io.EOF {
+		traceln("transporter.snapshotChunk.decoding.error:", err)
+		return nil
+	}
+	return resp
+}
+
+// SendSnapshotChunks streams a snapshot to peer in chunks read from source,
+// honoring HTTPTransporter.SnapshotChunkSize, rather than buffering the
+// whole thing in memory the way SendSnapshotRecoveryRequest does. If the
+// follower reports a NextOffset behind where source currently is, the
+// transfer resumes from there instead of restarting from zero.
+func (t *HTTPTransporter) SendSnapshotChunks(peer *Peer, snapshotID string, source io.ReadSeeker) error {
+	chunkSize := t.SnapshotChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultSnapshotChunkSize
+	}
+
+	size, err := source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	runningHash := crc32.NewIEEE()
+	var offset int64
+	sentAny := false
+
+	for offset < size || !sentAny {
+		sentAny = true
+		n, err := io.ReadFull(source, buf[:minInt(chunkSize, int(size-offset))])
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		data := buf[:n]
+		runningHash.Write(data)
+
+		done := offset+int64(n) >= size
+		checksum := crc32.ChecksumIEEE(data)
+		if done {
+			checksum = runningHash.Sum32()
+		}
+
+		req := NewSnapshotChunkRequest(snapshotID, uint64(offset), data, done, checksum)
+		resp := t.sendSnapshotChunk(peer, req)
+		if resp == nil {
+			return fmt.Errorf("transporter: snapshot chunk at offset %d failed", offset)
+		}
+		if !resp.Success {
+			return fmt.Errorf("transporter: follower rejected snapshot chunk at offset %d", offset)
+		}
+
+		if int64(resp.NextOffset) == offset+int64(n) {
+			offset = int64(resp.NextOffset)
+			continue
+		}
+
+		// The follower is at a different offset than we expected (a
+		// retransmit or a resumed connection); reseek and rebuild the
+		// running hash so the eventual Done checksum still covers exactly
+		// what the follower has.
+		offset = int64(resp.NextOffset)
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		runningHash = crc32.NewIEEE()
+		if _, err := io.CopyN(runningHash, source, offset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
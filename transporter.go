@@ -0,0 +1,54 @@
+package raft
+
+import stdcontext "context"
+
+// A Transporter is used to send RPCs between Raft servers. HTTPTransporter is
+// the default implementation, but any type satisfying this interface can be
+// plugged into a Server so the wire protocol can be swapped without touching
+// Server itself (e.g. to use gRPC instead of one-shot HTTP POSTs).
+type Transporter interface {
+	// Sends an AppendEntries RPC to a peer.
+	SendAppendEntriesRequest(server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse
+
+	// Sends a RequestVote RPC to a peer.
+	SendVoteRequest(server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse
+
+	// Sends a SnapshotRequest RPC to a peer.
+	SendSnapshotRequest(server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse
+
+	// Sends a SnapshotRecoveryRequest RPC to a peer.
+	SendSnapshotRecoveryRequest(server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse
+
+	// Binds the transporter's handlers for the given server to a router/mux.
+	// Transporters that are not HTTP based (e.g. GRPCTransporter) are free to
+	// ignore mux and expose their own registration method instead.
+	Install(server Server, mux HTTPMuxer)
+}
+
+// A ContextTransporter is a Transporter that also accepts a context on each
+// RPC, so a caller can bound or cancel an individual round trip (e.g. a
+// vote request for an election that has already ended) without affecting
+// any other in-flight call. It is a separate interface, rather than extra
+// methods on Transporter, so existing Transporter implementations outside
+// this package don't break; callers that want deadline/cancellation
+// support type-assert for it and fall back to the plain Transporter
+// methods (which is what every in-tree transporter's non-Context method
+// does internally, via context.Background()) when it isn't satisfied.
+type ContextTransporter interface {
+	Transporter
+
+	SendAppendEntriesRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *AppendEntriesRequest) *AppendEntriesResponse
+	SendVoteRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *RequestVoteRequest) *RequestVoteResponse
+	SendSnapshotRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRequest) *SnapshotResponse
+	SendSnapshotRecoveryRequestContext(ctx stdcontext.Context, server Server, peer *Peer, req *SnapshotRecoveryRequest) *SnapshotRecoveryResponse
+}
+
+// HTTPTransporter is the original, HTTP/protobuf based Transporter.
+var _ Transporter = (*HTTPTransporter)(nil)
+var _ ContextTransporter = (*HTTPTransporter)(nil)
+
+// GRPCTransporter also satisfies ContextTransporter: its Context variants
+// thread ctx through to the underlying unary gRPC call (AppendEntries is
+// the one exception — the stream's own context, fixed when it was opened,
+// governs it; see GRPCTransporter.SendAppendEntriesRequestContext).
+var _ ContextTransporter = (*GRPCTransporter)(nil)